@@ -0,0 +1,218 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Apply modes for the --apply-mode flag.
+const (
+	ApplyModeVPA       = "vpa"
+	ApplyModePatch     = "patch"
+	ApplyModeKustomize = "kustomize"
+)
+
+// applyRecommendations emits the chosen machine-consumable form of the
+// recommendations carried by every one of services. Run passes it only the
+// workloads with Action == "Need update".
+func applyRecommendations(cfg Config, services []Service) error {
+	switch cfg.ApplyMode {
+	case ApplyModeVPA:
+		return applyVPA(os.Stdout, services)
+	case ApplyModePatch:
+		return applyPatch(os.Stdout, services)
+	case ApplyModeKustomize:
+		return applyKustomize(cfg.OutDir, services)
+	default:
+		return fmt.Errorf("unknown apply mode %q, must be one of: %s, %s, %s", cfg.ApplyMode, ApplyModeVPA, ApplyModePatch, ApplyModeKustomize)
+	}
+}
+
+// vpaManifest is the subset of the autoscaling.k8s.io/v1
+// VerticalPodAutoscaler type this tool needs to emit a recommendation-only
+// (UpdateMode "Off") manifest.
+type vpaManifest struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   vpaMetadata `yaml:"metadata"`
+	Spec       vpaSpec     `yaml:"spec"`
+}
+
+type vpaMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+type vpaSpec struct {
+	TargetRef      vpaTargetRef      `yaml:"targetRef"`
+	UpdatePolicy   vpaUpdatePolicy   `yaml:"updatePolicy"`
+	ResourcePolicy vpaResourcePolicy `yaml:"resourcePolicy"`
+}
+
+type vpaTargetRef struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Name       string `yaml:"name"`
+}
+
+type vpaUpdatePolicy struct {
+	UpdateMode string `yaml:"updateMode"`
+}
+
+type vpaResourcePolicy struct {
+	ContainerPolicies []vpaContainerPolicy `yaml:"containerPolicies"`
+}
+
+type vpaContainerPolicy struct {
+	ContainerName string            `yaml:"containerName"`
+	MinAllowed    map[string]string `yaml:"minAllowed"`
+}
+
+// renderVPA emits one ContainerPolicy per real container, each carrying its
+// own share of s.Recommended. A single "*" policy would apply the
+// pod-level aggregate to every matching container individually, inflating
+// the effective request by a factor of the container count.
+func renderVPA(s Service) vpaManifest {
+	perContainer := perContainerRecommendations(s)
+	policies := make([]vpaContainerPolicy, 0, len(s.ContainerNames))
+	for i, name := range s.ContainerNames {
+		policies = append(policies, vpaContainerPolicy{
+			ContainerName: name,
+			MinAllowed: map[string]string{
+				"cpu":    perContainer[i].CPU.String(),
+				"memory": perContainer[i].Memory.String(),
+			},
+		})
+	}
+
+	return vpaManifest{
+		APIVersion: "autoscaling.k8s.io/v1",
+		Kind:       "VerticalPodAutoscaler",
+		Metadata:   vpaMetadata{Name: s.Name + "-recommendation", Namespace: s.Namespace},
+		Spec: vpaSpec{
+			TargetRef:      vpaTargetRef{APIVersion: "apps/v1", Kind: s.Kind, Name: s.Name},
+			UpdatePolicy:   vpaUpdatePolicy{UpdateMode: "Off"},
+			ResourcePolicy: vpaResourcePolicy{ContainerPolicies: policies},
+		},
+	}
+}
+
+func applyVPA(w *os.File, services []Service) error {
+	enc := yaml.NewEncoder(w)
+	defer enc.Close()
+	for _, s := range services {
+		if err := enc.Encode(renderVPA(s)); err != nil {
+			return fmt.Errorf("render vpa for %s/%s: %w", s.Namespace, s.Name, err)
+		}
+	}
+	return nil
+}
+
+// requestsPatch is the strategic-merge-patch body shared by the patch and
+// kustomize apply modes, updating every real container's resource requests
+// with its own share of s.Recommended (see perContainerRecommendations).
+// Unlike VPA's ContainerPolicy, the containers merge key in a PodSpec is the
+// container name, so "*" would not match anything and would instead append
+// a bogus extra container on apply.
+func requestsPatch(s Service) map[string]any {
+	perContainer := perContainerRecommendations(s)
+	containers := make([]map[string]any, 0, len(s.ContainerNames))
+	for i, name := range s.ContainerNames {
+		containers = append(containers, map[string]any{
+			"name": name,
+			"resources": map[string]any{
+				"requests": map[string]string{
+					"cpu":    perContainer[i].CPU.String(),
+					"memory": perContainer[i].Memory.String(),
+				},
+			},
+		})
+	}
+
+	podTemplate := map[string]any{
+		"spec": map[string]any{
+			"containers": containers,
+		},
+	}
+	if s.Kind == "CronJob" {
+		// A CronJob's pod template sits under its Job template.
+		return map[string]any{
+			"spec": map[string]any{
+				"jobTemplate": map[string]any{
+					"spec": map[string]any{"template": podTemplate},
+				},
+			},
+		}
+	}
+	return map[string]any{
+		"spec": map[string]any{
+			"template": podTemplate,
+		},
+	}
+}
+
+func applyPatch(w *os.File, services []Service) error {
+	for _, s := range services {
+		body, err := json.Marshal(requestsPatch(s))
+		if err != nil {
+			return fmt.Errorf("render patch for %s/%s: %w", s.Namespace, s.Name, err)
+		}
+		if _, err := fmt.Fprintf(w, "kubectl patch %s %s -n %s --type=strategic -p %q\n", s.Kind, s.Name, s.Namespace, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type kustomization struct {
+	APIVersion string           `yaml:"apiVersion"`
+	Kind       string           `yaml:"kind"`
+	Patches    []kustomizePatch `yaml:"patches"`
+}
+
+type kustomizePatch struct {
+	Path string `yaml:"path"`
+}
+
+// applyKustomize writes one strategic-merge-patch file per workload plus a
+// kustomization.yaml referencing them, into outDir.
+func applyKustomize(outDir string, services []Service) error {
+	if outDir == "" {
+		return fmt.Errorf("--out-dir is required for --apply-mode=%s", ApplyModeKustomize)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+
+	kz := kustomization{APIVersion: "kustomize.config.k8s.io/v1beta1", Kind: "Kustomization"}
+	for _, s := range services {
+		patch := requestsPatch(s)
+		patch["apiVersion"] = "apps/v1"
+		if s.Kind == "CronJob" {
+			patch["apiVersion"] = "batch/v1"
+		}
+		patch["kind"] = s.Kind
+		patch["metadata"] = map[string]string{"name": s.Name, "namespace": s.Namespace}
+
+		data, err := yaml.Marshal(patch)
+		if err != nil {
+			return fmt.Errorf("render kustomize patch for %s/%s: %w", s.Namespace, s.Name, err)
+		}
+
+		filename := fmt.Sprintf("%s_%s-patch.yaml", s.Namespace, s.Name)
+		if err := os.WriteFile(filepath.Join(outDir, filename), data, 0o644); err != nil {
+			return err
+		}
+		kz.Patches = append(kz.Patches, kustomizePatch{Path: filename})
+	}
+
+	data, err := yaml.Marshal(kz)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, "kustomization.yaml"), data, 0o644)
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// twoContainerService builds a Service with two containers whose original
+// requests are split 3:1, and a pod-level Recommended of 100m/160Mi.
+func twoContainerService() Service {
+	s := Service{Kind: "Deployment", Namespace: "ns", Name: "svc", Replicas: 1}
+	s.ContainerNames = []string{"app", "sidecar"}
+	s.ContainerRequests = []Resources{
+		{CPU: *resource.NewMilliQuantity(150, resource.DecimalSI), Memory: *resource.NewQuantity(150*1024*1024, resource.BinarySI)},
+		{CPU: *resource.NewMilliQuantity(50, resource.DecimalSI), Memory: *resource.NewQuantity(50*1024*1024, resource.BinarySI)},
+	}
+	s.Request.CPU = *resource.NewMilliQuantity(200, resource.DecimalSI)
+	s.Request.Memory = *resource.NewQuantity(200*1024*1024, resource.BinarySI)
+	s.Recommended.CPU = *resource.NewMilliQuantity(100, resource.DecimalSI)
+	s.Recommended.Memory = *resource.NewQuantity(160*1024*1024, resource.BinarySI)
+	return s
+}
+
+func TestPerContainerRecommendationsSplitsByRequestShare(t *testing.T) {
+	got := perContainerRecommendations(twoContainerService())
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %d, want 2", len(got))
+	}
+	if got[0].CPU.MilliValue() != 80 {
+		t.Errorf("app CPU = %vm, want 80m (75%% of 100m)", got[0].CPU.MilliValue())
+	}
+	if got[1].CPU.MilliValue() != 30 {
+		t.Errorf("sidecar CPU = %vm, want 30m (25%% of 100m, rounded up to a 10m step)", got[1].CPU.MilliValue())
+	}
+}
+
+func TestPerContainerRecommendationsEvenSplitWithoutRequests(t *testing.T) {
+	s := Service{ContainerNames: []string{"app", "sidecar"}}
+	s.Recommended.CPU = *resource.NewMilliQuantity(100, resource.DecimalSI)
+	s.Recommended.Memory = *resource.NewQuantity(160*1024*1024, resource.BinarySI)
+
+	got := perContainerRecommendations(s)
+	if got[0].CPU.MilliValue() != got[1].CPU.MilliValue() {
+		t.Errorf("expected an even split with no requests set, got %vm and %vm", got[0].CPU.MilliValue(), got[1].CPU.MilliValue())
+	}
+}
+
+func TestRequestsPatchDoesNotInflateMultiContainerRequest(t *testing.T) {
+	s := twoContainerService()
+	patch := requestsPatch(s)
+
+	spec := patch["spec"].(map[string]any)
+	template := spec["template"].(map[string]any)
+	podSpec := template["spec"].(map[string]any)
+	containers := podSpec["containers"].([]map[string]any)
+	if len(containers) != 2 {
+		t.Fatalf("len(containers) = %d, want 2", len(containers))
+	}
+
+	for i, name := range s.ContainerNames {
+		if containers[i]["name"] != name {
+			t.Errorf("containers[%d].name = %v, want %v", i, containers[i]["name"], name)
+		}
+	}
+
+	appCPU := containers[0]["resources"].(map[string]any)["requests"].(map[string]string)["cpu"]
+	sidecarCPU := containers[1]["resources"].(map[string]any)["requests"].(map[string]string)["cpu"]
+	if appCPU == sidecarCPU {
+		t.Errorf("app and sidecar both got cpu %q; the pod-level aggregate should be split, not duplicated", appCPU)
+	}
+}
+
+func TestRenderVPASplitsAcrossContainers(t *testing.T) {
+	manifest := renderVPA(twoContainerService())
+	if len(manifest.Spec.ResourcePolicy.ContainerPolicies) != 2 {
+		t.Fatalf("len(ContainerPolicies) = %d, want 2", len(manifest.Spec.ResourcePolicy.ContainerPolicies))
+	}
+
+	for _, policy := range manifest.Spec.ResourcePolicy.ContainerPolicies {
+		if policy.ContainerName == "*" {
+			t.Error("expected one policy per real container name, not a \"*\" wildcard")
+		}
+	}
+
+	app := manifest.Spec.ResourcePolicy.ContainerPolicies[0]
+	sidecar := manifest.Spec.ResourcePolicy.ContainerPolicies[1]
+	if app.MinAllowed["cpu"] == sidecar.MinAllowed["cpu"] {
+		t.Errorf("app and sidecar both got minAllowed cpu %q; the pod-level aggregate should be split, not duplicated", app.MinAllowed["cpu"])
+	}
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	RegisterHandler(cronJobHandler{})
+}
+
+type cronJobHandler struct{}
+
+func (cronJobHandler) Kind() string { return "CronJob" }
+
+// List reports on CronJobs using the pod template and requests from
+// Spec.JobTemplate, the same shape as a Deployment/StatefulSet's pod
+// template. Unlike those kinds, a CronJob has no single persistent pod
+// selector: each scheduled run creates a new Job with its own generated
+// selector. List samples the first currently-active run's Job, if any, for
+// usage; a CronJob with no active run at sample time reports zero
+// replicas/usage rather than guessing, the same as any other workload with
+// no running pods.
+func (cronJobHandler) List(ctx context.Context, cfg Config, ns corev1.Namespace) ([]Service, error) {
+	cronJobs, err := cfg.KubeClient.BatchV1().CronJobs(ns.GetName()).List(ctx, metav1.ListOptions{LabelSelector: cfg.WorkloadSelector})
+	if err != nil {
+		return nil, err
+	}
+	services := make([]Service, len(cronJobs.Items))
+
+	for i, cj := range cronJobs.Items {
+		services[i] = Service{
+			Kind:      "CronJob",
+			Namespace: cj.Namespace,
+			Name:      cj.Name,
+		}
+		for _, container := range cj.Spec.JobTemplate.Spec.Template.Spec.Containers {
+			containerRequest := Resources{CPU: *container.Resources.Requests.Cpu(), Memory: *container.Resources.Requests.Memory()}
+			services[i].ContainerNames = append(services[i].ContainerNames, container.Name)
+			services[i].ContainerRequests = append(services[i].ContainerRequests, containerRequest)
+			services[i].Request.CPU.Add(containerRequest.CPU)
+			services[i].Request.Memory.Add(containerRequest.Memory)
+		}
+
+		if len(cj.Status.Active) == 0 {
+			services[i] = verdict(services[i], cfg.SafetyMargin)
+			continue
+		}
+
+		job, err := cfg.KubeClient.BatchV1().Jobs(ns.GetName()).Get(ctx, cj.Status.Active[0].Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector, err := metav1.LabelSelectorAsMap(job.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+
+		usage, replicas, err := cfg.MetricsSource.Usage(ctx, Workload{
+			Kind:      "CronJob",
+			Namespace: ns.GetName(),
+			Name:      cj.Name,
+			Selector:  selector,
+		})
+		if err != nil {
+			return nil, err
+		}
+		services[i].Replicas = replicas
+		services[i].Usage = usage
+		services[i] = verdict(services[i], cfg.SafetyMargin)
+	}
+	return services, nil
+}
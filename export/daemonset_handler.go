@@ -0,0 +1,56 @@
+package export
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	RegisterHandler(daemonSetHandler{})
+}
+
+type daemonSetHandler struct{}
+
+func (daemonSetHandler) Kind() string { return "DaemonSet" }
+
+func (daemonSetHandler) List(ctx context.Context, cfg Config, ns corev1.Namespace) ([]Service, error) {
+	dses, err := cfg.KubeClient.AppsV1().DaemonSets(ns.GetName()).List(ctx, metav1.ListOptions{LabelSelector: cfg.WorkloadSelector})
+	if err != nil {
+		return nil, err
+	}
+	services := make([]Service, len(dses.Items))
+
+	for i, ds := range dses.Items {
+		selector, err := metav1.LabelSelectorAsMap(ds.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		services[i] = Service{
+			Kind:      "DaemonSet",
+			Namespace: ds.Namespace,
+			Name:      ds.Name,
+		}
+		for _, container := range ds.Spec.Template.Spec.Containers {
+			containerRequest := Resources{CPU: *container.Resources.Requests.Cpu(), Memory: *container.Resources.Requests.Memory()}
+			services[i].ContainerNames = append(services[i].ContainerNames, container.Name)
+			services[i].ContainerRequests = append(services[i].ContainerRequests, containerRequest)
+			services[i].Request.CPU.Add(containerRequest.CPU)
+			services[i].Request.Memory.Add(containerRequest.Memory)
+		}
+		usage, replicas, err := cfg.MetricsSource.Usage(ctx, Workload{
+			Kind:      "DaemonSet",
+			Namespace: ns.GetName(),
+			Name:      ds.Name,
+			Selector:  selector,
+		})
+		if err != nil {
+			return nil, err
+		}
+		services[i].Replicas = replicas
+		services[i].Usage = usage
+		services[i] = verdict(services[i], cfg.SafetyMargin)
+	}
+	return services, nil
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	RegisterHandler(deploymentHandler{})
+}
+
+type deploymentHandler struct{}
+
+func (deploymentHandler) Kind() string { return "Deployment" }
+
+func (deploymentHandler) List(ctx context.Context, cfg Config, ns corev1.Namespace) ([]Service, error) {
+	deploys, err := cfg.KubeClient.AppsV1().Deployments(ns.GetName()).List(ctx, metav1.ListOptions{LabelSelector: cfg.WorkloadSelector})
+	if err != nil {
+		return nil, err
+	}
+	services := make([]Service, len(deploys.Items))
+
+	for i, deploy := range deploys.Items {
+		selector, err := metav1.LabelSelectorAsMap(deploy.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		services[i] = Service{
+			Kind:      "Deployment",
+			Namespace: deploy.Namespace,
+			Name:      deploy.Name,
+		}
+		for _, container := range deploy.Spec.Template.Spec.Containers {
+			containerRequest := Resources{CPU: *container.Resources.Requests.Cpu(), Memory: *container.Resources.Requests.Memory()}
+			services[i].ContainerNames = append(services[i].ContainerNames, container.Name)
+			services[i].ContainerRequests = append(services[i].ContainerRequests, containerRequest)
+			services[i].Request.CPU.Add(containerRequest.CPU)
+			services[i].Request.Memory.Add(containerRequest.Memory)
+		}
+		usage, replicas, err := cfg.MetricsSource.Usage(ctx, Workload{
+			Kind:      "Deployment",
+			Namespace: ns.GetName(),
+			Name:      deploy.Name,
+			Selector:  selector,
+		})
+		if err != nil {
+			return nil, err
+		}
+		services[i].Replicas = replicas
+		services[i].Usage = usage
+		services[i] = verdict(services[i], cfg.SafetyMargin)
+	}
+	return services, nil
+}
@@ -0,0 +1,214 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+type Config struct {
+	KubeClient    *kubernetes.Clientset
+	MetricClient  *metrics.Clientset
+	MetricsSource MetricsSource
+
+	IgnoreNamespaces  []string
+	Namespaces        []string
+	NamespaceSelector string
+	WorkloadSelector  string
+	IncludeKinds      []string
+	Output            string
+	ApplyMode         string
+	OutDir            string
+	SafetyMargin      float64
+}
+
+type Resources struct {
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+type Service struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Replicas  int32
+	// ContainerNames and ContainerRequests are parallel slices: element i is
+	// the name and original resource request of the i-th container in the
+	// pod template, in container order.
+	ContainerNames    []string
+	ContainerRequests []Resources
+	Usage             Resources
+	Request           Resources
+	Recommended       Resources
+	Action            string
+	Note              string
+}
+
+func (o *Service) CSV() string {
+	if o == nil {
+		return ""
+	}
+	cpu := fmt.Sprintf("%vm/unlimit", o.Usage.CPU.MilliValue())
+	if !o.Request.CPU.IsZero() {
+		cpu = fmt.Sprintf("%vm/%vm", o.Usage.CPU.MilliValue(), o.Request.CPU.MilliValue())
+	}
+	memory := fmt.Sprintf("%vMi/unlimit", o.Usage.Memory.Value()/(1024*1024))
+	if !o.Request.Memory.IsZero() {
+		memory = fmt.Sprintf("%vMi/%vMi", o.Usage.Memory.Value()/(1024*1024), o.Request.Memory.Value()/(1024*1024))
+	}
+
+	return fmt.Sprintf("%s,%s,%s,%d,%s,%s,%s,%s",
+		o.Namespace,
+		o.Name,
+		o.Kind,
+		o.Replicas,
+		cpu,
+		memory,
+		o.Action,
+		o.Note)
+}
+
+// collectServices lists every in-scope workload across every in-scope
+// namespace and runs it through verdict. It's the shared core behind both
+// the default one-shot export and the serve subcommand's scrape loop.
+func collectServices(ctx context.Context, cfg Config) ([]Service, error) {
+	handlers, err := handlersFor(cfg.IncludeKinds)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := listNamespaces(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var services []Service
+	for _, ns := range namespaces {
+		for _, h := range handlers {
+			ss, err := h.List(ctx, cfg, ns)
+			if err != nil {
+				return nil, fmt.Errorf("list %s in namespace %s: %w", h.Kind(), ns.GetName(), err)
+			}
+			services = append(services, ss...)
+		}
+	}
+	return services, nil
+}
+
+func Run(cfg Config) error {
+	services, err := collectServices(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+
+	output := cfg.Output
+	if output == "" {
+		output = "csv"
+	}
+	reporter, err := reporterFor(output)
+	if err != nil {
+		return err
+	}
+	if err := reporter.Begin(os.Stdout); err != nil {
+		return err
+	}
+
+	var applyTargets []Service
+	for _, s := range services {
+		if err := reporter.Write(s); err != nil {
+			return err
+		}
+		if cfg.ApplyMode != "" && s.Action == "Need update" {
+			applyTargets = append(applyTargets, s)
+		}
+	}
+	if err := reporter.End(); err != nil {
+		return err
+	}
+
+	if cfg.ApplyMode != "" {
+		return applyRecommendations(cfg, applyTargets)
+	}
+	return nil
+}
+
+// verdict decides whether s needs attention and, if so, what its new request
+// should be: the observed per-pod usage times safetyMargin, rounded to a
+// sensible unit. A safetyMargin <= 0 falls back to DefaultSafetyMargin.
+func verdict(s Service, safetyMargin float64) Service {
+	if s.Replicas == 0 {
+		s.Action = "Need remove"
+		return s
+	}
+	s.Action = "Good"
+	if safetyMargin <= 0 {
+		safetyMargin = DefaultSafetyMargin
+	}
+
+	perPodCPU := s.Usage.CPU.MilliValue() / int64(s.Replicas)
+	s.Recommended.CPU = roundCPU(*milliCPUQuantity(int64(float64(perPodCPU) * safetyMargin)))
+	perPodMem := s.Usage.Memory.Value() / int64(s.Replicas)
+	s.Recommended.Memory = roundMemory(*memoryQuantity(int64(float64(perPodMem) * safetyMargin)))
+
+	if diff := (s.Request.CPU.MilliValue() - s.Usage.CPU.MilliValue()) / int64(s.Replicas); diff > 0 &&
+		diff > int64((10*s.Request.CPU.MilliValue())/int64(s.Replicas)/100) { // need update if the diff greater than 10% request
+		s.Action = "Need update"
+		s.Note = fmt.Sprintf("Need reduce CPU %.2f%%(%vm per pod, new request %vm)", percent(diff, s.Request.CPU.MilliValue()/int64(s.Replicas)), diff, s.Recommended.CPU.MilliValue())
+	}
+
+	if diff := (s.Request.Memory.Value() - s.Usage.Memory.Value()) / int64(s.Replicas); diff > 0 &&
+		diff > int64((10*s.Request.Memory.Value())/int64(s.Replicas)/100) { // need update if the diff greater than 10% request
+		s.Action = "Need update"
+		recMi := s.Recommended.Memory.Value() / (1024 * 1024)
+		if s.Note != "" {
+			s.Note = fmt.Sprintf("%s; Need reduce Memory %.2f%%(%vMi per pod, new request %vMi)", s.Note, percent(diff, s.Request.Memory.Value()/int64(s.Replicas)), diff/(1024*1024), recMi)
+		} else {
+			s.Note = fmt.Sprintf("Need reduce Memory %.2f%%(%vMi per pod, new request %vMi)", percent(diff, s.Request.Memory.Value()/int64(s.Replicas)), diff/(1024*1024), recMi)
+		}
+	}
+	return s
+}
+
+// perContainerRecommendations splits s.Recommended, a pod-level aggregate,
+// across s.ContainerNames in proportion to each container's original
+// request share of s.Request. When no container in the pod sets a request
+// at all, the aggregate is split evenly instead; when only some containers
+// set one, those without a request get a zero share. CPU and memory are
+// handled independently. The result is parallel to s.ContainerNames.
+func perContainerRecommendations(s Service) []Resources {
+	n := len(s.ContainerNames)
+	if n == 0 {
+		return nil
+	}
+
+	totalCPU := s.Request.CPU.MilliValue()
+	totalMem := s.Request.Memory.Value()
+	recommended := make([]Resources, n)
+	for i := range s.ContainerNames {
+		var containerRequest Resources
+		if i < len(s.ContainerRequests) {
+			containerRequest = s.ContainerRequests[i]
+		}
+
+		cpuShare := 1.0 / float64(n)
+		if totalCPU > 0 {
+			cpuShare = float64(containerRequest.CPU.MilliValue()) / float64(totalCPU)
+		}
+		memShare := 1.0 / float64(n)
+		if totalMem > 0 {
+			memShare = float64(containerRequest.Memory.Value()) / float64(totalMem)
+		}
+
+		recommended[i].CPU = roundCPU(*milliCPUQuantity(int64(float64(s.Recommended.CPU.MilliValue()) * cpuShare)))
+		recommended[i].Memory = roundMemory(*memoryQuantity(int64(float64(s.Recommended.Memory.Value()) * memShare)))
+	}
+	return recommended
+}
+
+func percent(in int64, all int64) float64 {
+	return (float64(in) / float64(all)) * float64(100)
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestVerdictNeedRemove(t *testing.T) {
+	s := Service{Replicas: 0}
+	got := verdict(s, DefaultSafetyMargin)
+	if got.Action != "Need remove" {
+		t.Errorf("Action = %q, want %q", got.Action, "Need remove")
+	}
+}
+
+func TestVerdictGood(t *testing.T) {
+	s := Service{Replicas: 1}
+	s.Usage.CPU = *resource.NewMilliQuantity(95, resource.DecimalSI)
+	s.Request.CPU = *resource.NewMilliQuantity(100, resource.DecimalSI)
+	s.Usage.Memory = *resource.NewQuantity(95, resource.BinarySI)
+	s.Request.Memory = *resource.NewQuantity(100, resource.BinarySI)
+
+	got := verdict(s, DefaultSafetyMargin)
+	if got.Action != "Good" {
+		t.Errorf("Action = %q, want %q", got.Action, "Good")
+	}
+}
+
+func TestVerdictNeedUpdateCPU(t *testing.T) {
+	s := Service{Replicas: 1}
+	s.Usage.CPU = *resource.NewMilliQuantity(50, resource.DecimalSI)
+	s.Request.CPU = *resource.NewMilliQuantity(200, resource.DecimalSI)
+
+	got := verdict(s, DefaultSafetyMargin)
+	if got.Action != "Need update" {
+		t.Errorf("Action = %q, want %q", got.Action, "Need update")
+	}
+	if got.Note == "" {
+		t.Error("Note should describe the recommended reduction")
+	}
+	wantCPU := roundCPU(*milliCPUQuantity(int64(float64(50) * DefaultSafetyMargin)))
+	if got.Recommended.CPU.MilliValue() != wantCPU.MilliValue() {
+		t.Errorf("Recommended.CPU = %vm, want %vm", got.Recommended.CPU.MilliValue(), wantCPU.MilliValue())
+	}
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// WorkloadHandler knows how to list the workloads of a single kind in a
+// namespace and turn them into Services, including their pod-selector logic
+// and request/usage aggregation.
+type WorkloadHandler interface {
+	// Kind returns the workload kind this handler is responsible for, e.g.
+	// "Deployment" or "StatefulSet".
+	Kind() string
+	// List returns the Services for every workload of this kind in ns.
+	List(ctx context.Context, cfg Config, ns corev1.Namespace) ([]Service, error)
+}
+
+var handlerRegistry = map[string]WorkloadHandler{}
+
+// RegisterHandler registers a WorkloadHandler under its Kind(). Handlers are
+// expected to register themselves from an init() function; registering the
+// same kind twice overwrites the previous handler.
+func RegisterHandler(h WorkloadHandler) {
+	handlerRegistry[h.Kind()] = h
+}
+
+// handlersFor resolves the registered handlers for the requested kinds,
+// preserving the order the kinds were given in. An empty kinds list selects
+// every registered handler.
+func handlersFor(kinds []string) ([]WorkloadHandler, error) {
+	if len(kinds) == 0 {
+		registeredKinds := make([]string, 0, len(handlerRegistry))
+		for kind := range handlerRegistry {
+			registeredKinds = append(registeredKinds, kind)
+		}
+		sort.Strings(registeredKinds)
+
+		handlers := make([]WorkloadHandler, 0, len(registeredKinds))
+		for _, kind := range registeredKinds {
+			handlers = append(handlers, handlerRegistry[kind])
+		}
+		return handlers, nil
+	}
+
+	handlers := make([]WorkloadHandler, 0, len(kinds))
+	for _, kind := range kinds {
+		h, ok := handlerRegistry[kind]
+		if !ok {
+			return nil, fmt.Errorf("no workload handler registered for kind %q", kind)
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers, nil
+}
+
+// listOptionsFromSelector builds the label selector used to find the pods
+// owned by a workload from its selector map.
+func listOptionsFromSelector(selector map[string]string) metav1.ListOptions {
+	return metav1.ListOptions{LabelSelector: labels.SelectorFromSet(selector).String()}
+}
@@ -0,0 +1,37 @@
+package export
+
+import "testing"
+
+func TestHandlersForResolvesAllBuiltinKinds(t *testing.T) {
+	for _, kind := range []string{"Deployment", "StatefulSet", "DaemonSet", "CronJob"} {
+		handlers, err := handlersFor([]string{kind})
+		if err != nil {
+			t.Errorf("handlersFor(%q) returned an error: %v", kind, err)
+			continue
+		}
+		if len(handlers) != 1 || handlers[0].Kind() != kind {
+			t.Errorf("handlersFor(%q) = %v, want a single %s handler", kind, handlers, kind)
+		}
+	}
+}
+
+func TestHandlersForUnknownKind(t *testing.T) {
+	if _, err := handlersFor([]string{"Job"}); err == nil {
+		t.Error("expected an error for an unregistered kind")
+	}
+}
+
+func TestHandlersForEmptyIsSortedAndIncludesEveryKind(t *testing.T) {
+	handlers, err := handlersFor(nil)
+	if err != nil {
+		t.Fatalf("handlersFor(nil): %v", err)
+	}
+	if len(handlers) != 4 {
+		t.Fatalf("len(handlers) = %d, want 4", len(handlers))
+	}
+	for i := 1; i < len(handlers); i++ {
+		if handlers[i-1].Kind() >= handlers[i].Kind() {
+			t.Errorf("handlers are not sorted by kind: %s before %s", handlers[i-1].Kind(), handlers[i].Kind())
+		}
+	}
+}
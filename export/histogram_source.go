@@ -0,0 +1,185 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// sample is one (decayed) usage observation recorded for a workload.
+type sample struct {
+	CPUMilli   int64   `json:"cpuMilli"`
+	MemoryByte int64   `json:"memoryByte"`
+	Weight     float64 `json:"weight"`
+}
+
+// workloadHistory is the decaying set of samples collected for a single
+// workload across repeated invocations of this tool.
+type workloadHistory struct {
+	Samples []sample `json:"samples"`
+}
+
+const (
+	// historyDecay is applied to every previously recorded sample's weight
+	// on each new run, so older samples count for less over time.
+	historyDecay = 0.9
+	// historyMaxSamples bounds how many samples are kept per workload.
+	historyMaxSamples = 200
+)
+
+// decayingHistogramSource wraps another MetricsSource (normally
+// metricsServerSource) and accumulates its instantaneous samples into a
+// decaying histogram persisted on disk under dir, so that percentiles can be
+// approximated locally across repeated runs when Prometheus isn't available.
+type decayingHistogramSource struct {
+	inner         MetricsSource
+	dir           string
+	cpuPercentile float64
+	memPercentile float64
+}
+
+// NewDecayingHistogramSource returns a MetricsSource that records inner's
+// samples to dir and reports the requested percentile over the accumulated
+// history instead of the latest instantaneous reading.
+func NewDecayingHistogramSource(inner MetricsSource, dir string, cpuPercentile, memPercentile float64) MetricsSource {
+	return decayingHistogramSource{inner: inner, dir: dir, cpuPercentile: cpuPercentile, memPercentile: memPercentile}
+}
+
+func (s decayingHistogramSource) Usage(ctx context.Context, w Workload) (Resources, int32, error) {
+	usage, replicas, err := s.inner.Usage(ctx, w)
+	if err != nil {
+		return Resources{}, 0, err
+	}
+
+	path := s.historyPath(w)
+	history, err := loadWorkloadHistory(path)
+	if err != nil {
+		return Resources{}, 0, fmt.Errorf("load local history for %s/%s: %w", w.Namespace, w.Name, err)
+	}
+
+	history.record(usage, replicas)
+	if err := saveWorkloadHistory(path, history); err != nil {
+		return Resources{}, 0, fmt.Errorf("save local history for %s/%s: %w", w.Namespace, w.Name, err)
+	}
+
+	// The history holds per-pod samples; scale the percentile back up to the
+	// workload-wide aggregate that MetricsSource callers expect.
+	cpu := history.percentileCPU(s.cpuPercentile)
+	mem := history.percentileMemory(s.memPercentile)
+	if replicas > 0 {
+		cpu *= int64(replicas)
+		mem *= int64(replicas)
+	}
+
+	var result Resources
+	result.CPU = *milliCPUQuantity(cpu)
+	result.Memory = *memoryQuantity(mem)
+	return result, replicas, nil
+}
+
+func (s decayingHistogramSource) historyPath(w Workload) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s_%s_%s.json", w.Namespace, w.Kind, w.Name))
+}
+
+func (h *workloadHistory) record(usage Resources, replicas int32) {
+	for i := range h.Samples {
+		h.Samples[i].Weight *= historyDecay
+	}
+
+	perPodCPU := usage.CPU.MilliValue()
+	perPodMem := usage.Memory.Value()
+	if replicas > 0 {
+		perPodCPU /= int64(replicas)
+		perPodMem /= int64(replicas)
+	}
+
+	h.Samples = append(h.Samples, sample{CPUMilli: perPodCPU, MemoryByte: perPodMem, Weight: 1})
+	if len(h.Samples) > historyMaxSamples {
+		h.Samples = h.Samples[len(h.Samples)-historyMaxSamples:]
+	}
+}
+
+func (h workloadHistory) percentileCPU(p float64) int64 {
+	values := make([]int64, len(h.Samples))
+	for i, s := range h.Samples {
+		values[i] = s.CPUMilli
+	}
+	return weightedPercentile(values, weightsOf(h.Samples), p)
+}
+
+func (h workloadHistory) percentileMemory(p float64) int64 {
+	values := make([]int64, len(h.Samples))
+	for i, s := range h.Samples {
+		values[i] = s.MemoryByte
+	}
+	return weightedPercentile(values, weightsOf(h.Samples), p)
+}
+
+func weightsOf(samples []sample) []float64 {
+	weights := make([]float64, len(samples))
+	for i, s := range samples {
+		weights[i] = s.Weight
+	}
+	return weights
+}
+
+// weightedPercentile returns the value at percentile p (0..1) of values,
+// weighted by weights. It's a simple weighted nearest-rank implementation,
+// sufficient for a local approximation of Prometheus's quantile_over_time.
+func weightedPercentile(values []int64, weights []float64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	type pair struct {
+		value  int64
+		weight float64
+	}
+	pairs := make([]pair, len(values))
+	total := 0.0
+	for i := range values {
+		pairs[i] = pair{value: values[i], weight: weights[i]}
+		total += weights[i]
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].value < pairs[j].value })
+
+	target := p * total
+	cum := 0.0
+	for _, pr := range pairs {
+		cum += pr.weight
+		if cum >= target {
+			return pr.value
+		}
+	}
+	return pairs[len(pairs)-1].value
+}
+
+func loadWorkloadHistory(path string) (*workloadHistory, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &workloadHistory{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var h workloadHistory
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func saveWorkloadHistory(path string, h *workloadHistory) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
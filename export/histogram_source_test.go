@@ -0,0 +1,60 @@
+package export
+
+import "testing"
+
+func TestWeightedPercentile(t *testing.T) {
+	values := []int64{10, 20, 30, 40, 50}
+	weights := []float64{1, 1, 1, 1, 1}
+
+	if got := weightedPercentile(values, weights, 0.5); got != 30 {
+		t.Errorf("p50 = %d, want 30", got)
+	}
+	if got := weightedPercentile(values, weights, 1); got != 50 {
+		t.Errorf("p100 = %d, want 50", got)
+	}
+	if got := weightedPercentile(nil, nil, 0.5); got != 0 {
+		t.Errorf("percentile of empty input = %d, want 0", got)
+	}
+}
+
+func TestWeightedPercentileDecay(t *testing.T) {
+	// A heavily decayed old sample should barely influence the percentile
+	// compared to a fresh one of the same weight class.
+	values := []int64{1000, 10}
+	weights := []float64{0.01, 1}
+
+	if got := weightedPercentile(values, weights, 0.9); got != 10 {
+		t.Errorf("decayed percentile = %d, want 10", got)
+	}
+}
+
+func TestWorkloadHistoryRecordPerPod(t *testing.T) {
+	var h workloadHistory
+	usage := Resources{}
+	usage.CPU = *milliCPUQuantity(200)
+	usage.Memory = *memoryQuantity(2048)
+
+	h.record(usage, 2)
+	if len(h.Samples) != 1 {
+		t.Fatalf("len(Samples) = %d, want 1", len(h.Samples))
+	}
+	if h.Samples[0].CPUMilli != 100 {
+		t.Errorf("per-pod CPUMilli = %d, want 100", h.Samples[0].CPUMilli)
+	}
+	if h.Samples[0].MemoryByte != 1024 {
+		t.Errorf("per-pod MemoryByte = %d, want 1024", h.Samples[0].MemoryByte)
+	}
+}
+
+func TestWorkloadHistoryDecaysOnRecord(t *testing.T) {
+	var h workloadHistory
+	h.record(Resources{}, 1)
+	h.record(Resources{}, 1)
+
+	if h.Samples[0].Weight != historyDecay {
+		t.Errorf("older sample weight = %v, want %v", h.Samples[0].Weight, historyDecay)
+	}
+	if h.Samples[1].Weight != 1 {
+		t.Errorf("newest sample weight = %v, want 1", h.Samples[1].Weight)
+	}
+}
@@ -0,0 +1,50 @@
+package export
+
+import (
+	"context"
+
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Workload describes the pods a MetricsSource should aggregate usage for.
+type Workload struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Selector  map[string]string
+}
+
+// MetricsSource produces an aggregated CPU/Memory usage reading for the pods
+// backing a Workload, along with how many pods were sampled. Implementations
+// are free to return an instantaneous reading (metrics-server) or a
+// percentile over a lookback window (Prometheus).
+type MetricsSource interface {
+	Usage(ctx context.Context, w Workload) (usage Resources, replicas int32, err error)
+}
+
+// metricsServerSource reads a single point-in-time sample from
+// metrics-server, the original behavior of this tool.
+type metricsServerSource struct {
+	client *metrics.Clientset
+}
+
+// NewMetricsServerSource returns a MetricsSource backed by metrics-server.
+func NewMetricsServerSource(client *metrics.Clientset) MetricsSource {
+	return metricsServerSource{client: client}
+}
+
+func (s metricsServerSource) Usage(ctx context.Context, w Workload) (Resources, int32, error) {
+	podMetrics, err := s.client.MetricsV1beta1().PodMetricses(w.Namespace).List(ctx, listOptionsFromSelector(w.Selector))
+	if err != nil {
+		return Resources{}, 0, err
+	}
+
+	var usage Resources
+	for _, m := range podMetrics.Items {
+		for _, container := range m.Containers {
+			usage.CPU.Add(*container.Usage.Cpu())
+			usage.Memory.Add(*container.Usage.Memory())
+		}
+	}
+	return usage, int32(len(podMetrics.Items)), nil
+}
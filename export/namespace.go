@@ -0,0 +1,45 @@
+package export
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// listNamespaces resolves the namespaces export should report on, honoring
+// (in order of precedence) the --namespaces allow-list, the
+// --namespace-selector label selector, and finally the --ignore-namespaces
+// deny-list against every namespace in the cluster.
+func listNamespaces(ctx context.Context, cfg Config) ([]corev1.Namespace, error) {
+	if len(cfg.Namespaces) > 0 {
+		namespaces := make([]corev1.Namespace, 0, len(cfg.Namespaces))
+		for _, name := range cfg.Namespaces {
+			ns, err := cfg.KubeClient.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("get namespace %s: %w", name, err)
+			}
+			namespaces = append(namespaces, *ns)
+		}
+		return namespaces, nil
+	}
+
+	list, err := cfg.KubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{
+		LabelSelector: cfg.NamespaceSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ignoreNamespaces := sets.New(cfg.IgnoreNamespaces...)
+	namespaces := make([]corev1.Namespace, 0, len(list.Items))
+	for _, ns := range list.Items {
+		if ignoreNamespaces.Has(ns.GetName()) {
+			continue
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
+}
@@ -0,0 +1,212 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusConfig configures a percentile-based MetricsSource backed by a
+// Prometheus (or compatible, e.g. Thanos/Mimir) server.
+type PrometheusConfig struct {
+	URL           string
+	Lookback      time.Duration
+	CPUPercentile float64
+	MemPercentile float64
+}
+
+// prometheusSource implements MetricsSource by running quantile_over_time
+// PromQL queries against container_cpu_usage_seconds_total and
+// container_memory_working_set_bytes, joined on the namespace/pod owned by
+// the workload.
+type prometheusSource struct {
+	cfg    PrometheusConfig
+	client *http.Client
+}
+
+// NewPrometheusSource returns a MetricsSource that reads percentile usage
+// from Prometheus instead of a single metrics-server sample.
+func NewPrometheusSource(cfg PrometheusConfig) MetricsSource {
+	return prometheusSource{cfg: cfg, client: http.DefaultClient}
+}
+
+func (s prometheusSource) Usage(ctx context.Context, w Workload) (Resources, int32, error) {
+	pods, err := s.resolvePodNames(ctx, w)
+	if err != nil {
+		return Resources{}, 0, fmt.Errorf("resolve pods owned by %s/%s: %w", w.Namespace, w.Name, err)
+	}
+	if len(pods) == 0 {
+		return Resources{}, 0, nil
+	}
+	podRegex := anchoredAlternation(pods)
+
+	cpu, err := s.queryScalar(ctx, fmt.Sprintf(
+		`quantile_over_time(%g, sum(rate(container_cpu_usage_seconds_total{namespace=%q,pod=~%q}[5m]))[%s:5m])`,
+		s.cfg.CPUPercentile, w.Namespace, podRegex, promDuration(s.cfg.Lookback)))
+	if err != nil {
+		return Resources{}, 0, fmt.Errorf("query cpu percentile: %w", err)
+	}
+
+	mem, err := s.queryScalar(ctx, fmt.Sprintf(
+		`quantile_over_time(%g, sum(container_memory_working_set_bytes{namespace=%q,pod=~%q})[%s:5m])`,
+		s.cfg.MemPercentile, w.Namespace, podRegex, promDuration(s.cfg.Lookback)))
+	if err != nil {
+		return Resources{}, 0, fmt.Errorf("query memory percentile: %w", err)
+	}
+
+	usage := Resources{}
+	usage.CPU = *milliCPUQuantity(int64(cpu * 1000))
+	usage.Memory = *memoryQuantity(int64(mem))
+	return usage, int32(len(pods)), nil
+}
+
+// resolvePodNames walks the real owner chain (kube-state-metrics'
+// kube_pod_owner / kube_replicaset_owner) instead of guessing pod names from
+// a name prefix, so workloads whose names prefix one another (e.g.
+// "checkout" and "checkout-canary") never contaminate each other's usage.
+func (s prometheusSource) resolvePodNames(ctx context.Context, w Workload) ([]string, error) {
+	switch w.Kind {
+	case "StatefulSet", "DaemonSet":
+		// Pods are owned directly by the StatefulSet/DaemonSet.
+		return s.podNamesOwnedBy(ctx, w.Namespace, w.Kind, []string{w.Name})
+	case "CronJob":
+		// Pod -> Job -> CronJob.
+		jobs, err := s.queryLabelValues(ctx, fmt.Sprintf(
+			`kube_job_owner{namespace=%q,owner_kind="CronJob",owner_name=%q}`, w.Namespace, w.Name), "job_name")
+		if err != nil {
+			return nil, fmt.Errorf("resolve jobs owned by %s: %w", w.Name, err)
+		}
+		if len(jobs) == 0 {
+			return nil, nil
+		}
+		return s.podNamesOwnedBy(ctx, w.Namespace, "Job", jobs)
+	default:
+		// Deployment (and anything else ReplicaSet-backed): Pod -> ReplicaSet -> w.Kind.
+		replicaSets, err := s.queryLabelValues(ctx, fmt.Sprintf(
+			`kube_replicaset_owner{namespace=%q,owner_kind=%q,owner_name=%q}`, w.Namespace, w.Kind, w.Name), "replicaset")
+		if err != nil {
+			return nil, fmt.Errorf("resolve replicasets owned by %s: %w", w.Name, err)
+		}
+		if len(replicaSets) == 0 {
+			return nil, nil
+		}
+		return s.podNamesOwnedBy(ctx, w.Namespace, "ReplicaSet", replicaSets)
+	}
+}
+
+func (s prometheusSource) podNamesOwnedBy(ctx context.Context, namespace, ownerKind string, ownerNames []string) ([]string, error) {
+	query := fmt.Sprintf(`kube_pod_owner{namespace=%q,owner_kind=%q,owner_name=~%q}`,
+		namespace, ownerKind, anchoredAlternation(ownerNames))
+	return s.queryLabelValues(ctx, query, "pod")
+}
+
+// anchoredAlternation builds a fully-anchored regex alternation so it can
+// only ever match the exact values given, never a workload whose name
+// happens to prefix another one.
+func anchoredAlternation(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = regexp.QuoteMeta(v)
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
+
+func promDuration(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	if days > 0 && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", days)
+	}
+	return d.String()
+}
+
+// promQueryResponse is the subset of the Prometheus HTTP API's instant query
+// response this tool cares about.
+type promQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]interface{}    `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+func (s prometheusSource) query(ctx context.Context, query string) (*promQueryResponse, error) {
+	u, err := url.Parse(s.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/api/v1/query"
+	q := u.Query()
+	q.Set("query", query)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed promQueryResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if parsed.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s", parsed.Error)
+	}
+	return &parsed, nil
+}
+
+// queryScalar runs query and returns the value of its single resulting
+// series. Use this only for queries already aggregated down to one series
+// (e.g. wrapped in sum(...)).
+func (s prometheusSource) queryScalar(ctx context.Context, query string) (float64, error) {
+	parsed, err := s.query(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	if len(parsed.Data.Result) == 0 {
+		return 0, nil
+	}
+
+	raw, ok := parsed.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected value type in prometheus response")
+	}
+	return strconv.ParseFloat(raw, 64)
+}
+
+// queryLabelValues runs query and returns the value of label off every
+// resulting series, for resolving owner-chain joins.
+func (s prometheusSource) queryLabelValues(ctx context.Context, query, label string) ([]string, error) {
+	parsed, err := s.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(parsed.Data.Result))
+	for _, r := range parsed.Data.Result {
+		if v, ok := r.Metric[label]; ok {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,232 @@
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Reporter renders a stream of Services in a particular output format. Begin
+// is called once before any Service, Write once per Service, and End once
+// after the last one so formats that need to wrap the whole stream (JSON,
+// YAML, JUnit) can flush a single document.
+type Reporter interface {
+	Begin(w io.Writer) error
+	Write(s Service) error
+	End() error
+}
+
+var reporterRegistry = map[string]func() Reporter{}
+
+// RegisterReporter registers a Reporter factory under name, for use with the
+// --output flag.
+func RegisterReporter(name string, factory func() Reporter) {
+	reporterRegistry[name] = factory
+}
+
+// reporterFor resolves the Reporter registered under name.
+func reporterFor(name string) (Reporter, error) {
+	factory, ok := reporterRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q", name)
+	}
+	return factory(), nil
+}
+
+func init() {
+	RegisterReporter("csv", func() Reporter { return &csvReporter{} })
+	RegisterReporter("json", func() Reporter { return &jsonReporter{} })
+	RegisterReporter("yaml", func() Reporter { return &yamlReporter{} })
+	RegisterReporter("table", func() Reporter { return &tableReporter{} })
+	RegisterReporter("markdown", func() Reporter { return &markdownReporter{} })
+	RegisterReporter("junit", func() Reporter { return &junitReporter{} })
+}
+
+// csvReporter is the original comma-separated output of this tool.
+type csvReporter struct {
+	w io.Writer
+}
+
+func (r *csvReporter) Begin(w io.Writer) error {
+	r.w = w
+	_, err := fmt.Fprintln(r.w, "Namespace,Name,Kind,Replicas,CPU Usage/CPU Request(m),Memory Usage/Memory Request(Mi),Action,Note")
+	return err
+}
+
+func (r *csvReporter) Write(s Service) error {
+	_, err := fmt.Fprintln(r.w, s.CSV())
+	return err
+}
+
+func (r *csvReporter) End() error { return nil }
+
+// jsonReporter buffers every Service and writes a single JSON array at End.
+type jsonReporter struct {
+	w        io.Writer
+	services []Service
+}
+
+func (r *jsonReporter) Begin(w io.Writer) error {
+	r.w = w
+	return nil
+}
+
+func (r *jsonReporter) Write(s Service) error {
+	r.services = append(r.services, s)
+	return nil
+}
+
+func (r *jsonReporter) End() error {
+	services := r.services
+	if services == nil {
+		services = []Service{}
+	}
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(services)
+}
+
+// yamlReporter buffers every Service and writes a single YAML list at End.
+type yamlReporter struct {
+	w        io.Writer
+	services []Service
+}
+
+func (r *yamlReporter) Begin(w io.Writer) error {
+	r.w = w
+	return nil
+}
+
+func (r *yamlReporter) Write(s Service) error {
+	r.services = append(r.services, s)
+	return nil
+}
+
+func (r *yamlReporter) End() error {
+	return yaml.NewEncoder(r.w).Encode(r.services)
+}
+
+// tableReporter renders a human-readable, column-aligned table for
+// terminals, in the style of `kubectl get`.
+type tableReporter struct {
+	w  io.Writer
+	tw *tabwriter.Writer
+}
+
+func (r *tableReporter) Begin(w io.Writer) error {
+	r.w = w
+	r.tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, err := fmt.Fprintln(r.tw, "NAMESPACE\tNAME\tKIND\tREPLICAS\tCPU\tMEMORY\tACTION\tNOTE")
+	return err
+}
+
+func (r *tableReporter) Write(s Service) error {
+	cpu, memory := usageColumns(s)
+	_, err := fmt.Fprintf(r.tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+		s.Namespace, s.Name, s.Kind, s.Replicas, cpu, memory, s.Action, s.Note)
+	return err
+}
+
+func (r *tableReporter) End() error {
+	return r.tw.Flush()
+}
+
+// markdownReporter renders a GitHub-flavored Markdown table, suitable for
+// embedding in PR comments or wiki pages.
+type markdownReporter struct {
+	w io.Writer
+}
+
+func (r *markdownReporter) Begin(w io.Writer) error {
+	r.w = w
+	if _, err := fmt.Fprintln(r.w, "| Namespace | Name | Kind | Replicas | CPU | Memory | Action | Note |"); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(r.w, "|---|---|---|---|---|---|---|---|")
+	return err
+}
+
+func (r *markdownReporter) Write(s Service) error {
+	cpu, memory := usageColumns(s)
+	_, err := fmt.Fprintf(r.w, "| %s | %s | %s | %d | %s | %s | %s | %s |\n",
+		s.Namespace, s.Name, s.Kind, s.Replicas, cpu, memory, s.Action, s.Note)
+	return err
+}
+
+func (r *markdownReporter) End() error { return nil }
+
+// junitReporter renders the report as a JUnit XML testsuite, one testcase
+// per workload, so CI pipelines can gate on "Need update"/"Need remove"
+// verdicts the same way they gate on test failures.
+type junitReporter struct {
+	w     io.Writer
+	cases []junitTestCase
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (r *junitReporter) Begin(w io.Writer) error {
+	r.w = w
+	return nil
+}
+
+func (r *junitReporter) Write(s Service) error {
+	tc := junitTestCase{Name: s.Name, Classname: fmt.Sprintf("%s.%s", s.Namespace, s.Kind)}
+	if s.Action == "Need update" || s.Action == "Need remove" {
+		tc.Failure = &junitFailure{Message: s.Action, Text: s.Note}
+	}
+	r.cases = append(r.cases, tc)
+	return nil
+}
+
+func (r *junitReporter) End() error {
+	failures := 0
+	for _, tc := range r.cases {
+		if tc.Failure != nil {
+			failures++
+		}
+	}
+	suite := junitTestSuite{Name: "resources-usage", Tests: len(r.cases), Failures: failures, TestCases: r.cases}
+
+	if _, err := io.WriteString(r.w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(r.w)
+	enc.Indent("", "  ")
+	return enc.Encode(suite)
+}
+
+// usageColumns renders the "used/requested" columns shared by the
+// human-readable reporters.
+func usageColumns(s Service) (cpu, memory string) {
+	cpu = fmt.Sprintf("%vm/unlimit", s.Usage.CPU.MilliValue())
+	if !s.Request.CPU.IsZero() {
+		cpu = fmt.Sprintf("%vm/%vm", s.Usage.CPU.MilliValue(), s.Request.CPU.MilliValue())
+	}
+	memory = fmt.Sprintf("%vMi/unlimit", s.Usage.Memory.Value()/(1024*1024))
+	if !s.Request.Memory.IsZero() {
+		memory = fmt.Sprintf("%vMi/%vMi", s.Usage.Memory.Value()/(1024*1024), s.Request.Memory.Value()/(1024*1024))
+	}
+	return cpu, memory
+}
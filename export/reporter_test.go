@@ -0,0 +1,106 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func sampleService() Service {
+	s := Service{Kind: "Deployment", Namespace: "ns", Name: "svc", Replicas: 2, Action: "Good"}
+	s.Usage.CPU = *resource.NewMilliQuantity(100, resource.DecimalSI)
+	s.Request.CPU = *resource.NewMilliQuantity(200, resource.DecimalSI)
+	return s
+}
+
+func runReporter(t *testing.T, r Reporter, services []Service) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := r.Begin(&buf); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	for _, s := range services {
+		if err := r.Write(s); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := r.End(); err != nil {
+		t.Fatalf("End: %v", err)
+	}
+	return buf.String()
+}
+
+func TestCSVReporter(t *testing.T) {
+	out := runReporter(t, &csvReporter{}, []Service{sampleService()})
+	if !strings.Contains(out, "ns,svc,Deployment,2") {
+		t.Errorf("csv output missing expected row: %q", out)
+	}
+}
+
+func TestJSONReporterEmptyIsArray(t *testing.T) {
+	out := runReporter(t, &jsonReporter{}, nil)
+	var decoded []Service
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("expected a JSON array, got %q: %v", out, err)
+	}
+	if decoded != nil && len(decoded) != 0 {
+		t.Errorf("expected empty slice, got %v", decoded)
+	}
+	if strings.TrimSpace(out) == "null" {
+		t.Errorf("expected [], got null")
+	}
+}
+
+func TestJSONReporterNonEmpty(t *testing.T) {
+	out := runReporter(t, &jsonReporter{}, []Service{sampleService()})
+	var decoded []Service
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0].Name != "svc" {
+		t.Errorf("unexpected decoded services: %+v", decoded)
+	}
+}
+
+func TestYAMLReporterEmptyIsList(t *testing.T) {
+	out := runReporter(t, &yamlReporter{}, nil)
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("expected yaml [], got %q", out)
+	}
+}
+
+func TestTableReporter(t *testing.T) {
+	out := runReporter(t, &tableReporter{}, []Service{sampleService()})
+	if !strings.Contains(out, "NAMESPACE") || !strings.Contains(out, "svc") {
+		t.Errorf("table output missing header/row: %q", out)
+	}
+}
+
+func TestMarkdownReporter(t *testing.T) {
+	out := runReporter(t, &markdownReporter{}, []Service{sampleService()})
+	if !strings.Contains(out, "| Namespace |") || !strings.Contains(out, "| svc |") {
+		t.Errorf("markdown output missing header/row: %q", out)
+	}
+}
+
+func TestJUnitReporter(t *testing.T) {
+	s := sampleService()
+	s.Action = "Need update"
+	s.Note = "Need reduce CPU"
+	out := runReporter(t, &junitReporter{}, []Service{s})
+	if !strings.Contains(out, `tests="1"`) || !strings.Contains(out, `failures="1"`) {
+		t.Errorf("junit output missing counts: %q", out)
+	}
+	if !strings.Contains(out, "<failure") {
+		t.Errorf("junit output missing failure element: %q", out)
+	}
+}
+
+func TestReporterForUnknownFormat(t *testing.T) {
+	if _, err := reporterFor("bogus"); err == nil {
+		t.Error("expected an error for an unknown output format")
+	}
+}
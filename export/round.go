@@ -0,0 +1,41 @@
+package export
+
+import "k8s.io/apimachinery/pkg/api/resource"
+
+const (
+	cpuStepMilli        = 10
+	memoryStepBytes     = 16 * 1024 * 1024
+	DefaultSafetyMargin = 1.2
+)
+
+// roundUpStep rounds v up to the next multiple of step, leaving v unchanged
+// if it already is one.
+func roundUpStep(v, step int64) int64 {
+	if step <= 0 {
+		return v
+	}
+	if r := v % step; r != 0 {
+		return v + (step - r)
+	}
+	return v
+}
+
+// roundCPU rounds a CPU quantity up to the nearest 10m step.
+func roundCPU(q resource.Quantity) resource.Quantity {
+	return *resource.NewMilliQuantity(roundUpStep(q.MilliValue(), cpuStepMilli), resource.DecimalSI)
+}
+
+// roundMemory rounds a memory quantity up to the nearest 16Mi step.
+func roundMemory(q resource.Quantity) resource.Quantity {
+	return *resource.NewQuantity(roundUpStep(q.Value(), memoryStepBytes), resource.BinarySI)
+}
+
+// milliCPUQuantity builds a CPU resource.Quantity from a millicore value.
+func milliCPUQuantity(milli int64) *resource.Quantity {
+	return resource.NewMilliQuantity(milli, resource.DecimalSI)
+}
+
+// memoryQuantity builds a memory resource.Quantity from a byte value.
+func memoryQuantity(bytes int64) *resource.Quantity {
+	return resource.NewQuantity(bytes, resource.BinarySI)
+}
@@ -0,0 +1,38 @@
+package export
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestRoundUpStep(t *testing.T) {
+	cases := []struct {
+		v, step, want int64
+	}{
+		{v: 0, step: 10, want: 0},
+		{v: 1, step: 10, want: 10},
+		{v: 10, step: 10, want: 10},
+		{v: 11, step: 10, want: 20},
+		{v: 123, step: 0, want: 123},
+	}
+	for _, c := range cases {
+		if got := roundUpStep(c.v, c.step); got != c.want {
+			t.Errorf("roundUpStep(%d, %d) = %d, want %d", c.v, c.step, got, c.want)
+		}
+	}
+}
+
+func TestRoundCPU(t *testing.T) {
+	got := roundCPU(*resource.NewMilliQuantity(123, resource.DecimalSI))
+	if got.MilliValue() != 130 {
+		t.Errorf("roundCPU(123m) = %vm, want 130m", got.MilliValue())
+	}
+}
+
+func TestRoundMemory(t *testing.T) {
+	got := roundMemory(*resource.NewQuantity(1, resource.BinarySI))
+	if got.Value() != memoryStepBytes {
+		t.Errorf("roundMemory(1) = %v, want %v", got.Value(), memoryStepBytes)
+	}
+}
@@ -0,0 +1,202 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/martian/v3/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+)
+
+// driftThreshold is the default number of consecutive scrapes a workload has
+// to report "Need update" for before it's considered a sustained drift
+// rather than a noisy blip.
+const driftThreshold = 3
+
+var (
+	cpuRequestGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "resources_usage_cpu_request_millicores",
+		Help: "Configured CPU request, in millicores, summed across containers.",
+	}, []string{"namespace", "workload", "kind"})
+	cpuUsedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "resources_usage_cpu_used_millicores",
+		Help: "Observed CPU usage, in millicores, summed across all of the workload's pods and containers.",
+	}, []string{"namespace", "workload", "kind"})
+	memRequestGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "resources_usage_memory_request_bytes",
+		Help: "Configured memory request, in bytes, summed across containers.",
+	}, []string{"namespace", "workload", "kind"})
+	memUsedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "resources_usage_memory_used_bytes",
+		Help: "Observed memory usage, in bytes, summed across all of the workload's pods and containers.",
+	}, []string{"namespace", "workload", "kind"})
+	verdictGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "resources_usage_verdict",
+		Help: "1 if the workload currently carries this verdict, 0 otherwise.",
+	}, []string{"namespace", "workload", "kind", "action"})
+	driftGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "resources_usage_drift_scrapes",
+		Help: "Number of consecutive scrapes a workload has reported Need update for.",
+	}, []string{"namespace", "workload", "kind"})
+)
+
+func init() {
+	prometheus.MustRegister(cpuRequestGauge, cpuUsedGauge, memRequestGauge, memUsedGauge, verdictGauge, driftGauge)
+}
+
+// snapshotStore holds the most recent export result for the /report.* and
+// /readyz endpoints, guarded by mu since it's written by the scrape loop and
+// read from HTTP handlers.
+type snapshotStore struct {
+	mu       sync.RWMutex
+	services []Service
+	ready    bool
+}
+
+func (s *snapshotStore) set(services []Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.services = services
+	s.ready = true
+}
+
+func (s *snapshotStore) get() ([]Service, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.services, s.ready
+}
+
+func NewServeCommand(exportCfgFn func() (Config, error)) *cobra.Command {
+	var addr string
+	var interval time.Duration
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the export loop on an interval and expose the results as a Prometheus /metrics endpoint",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := &snapshotStore{}
+			drift := map[string]int{}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+				if _, ready := store.get(); !ready {
+					http.Error(w, "not ready: no successful scrape yet", http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			mux.HandleFunc("/report.csv", reportHandler(store, "csv"))
+			mux.HandleFunc("/report.json", reportHandler(store, "json"))
+			mux.Handle("/metrics", promhttp.Handler())
+
+			server := &http.Server{Addr: addr, Handler: mux}
+			go func() {
+				log.Infof("serve: listening on %s", addr)
+				if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Errorf("serve: http server stopped: %v", err)
+				}
+			}()
+
+			ctx := cmd.Context()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				cfg, err := exportCfgFn()
+				if err != nil {
+					log.Errorf("serve: build config failed: %v", err)
+				} else if err := scrape(ctx, cfg, store, drift); err != nil {
+					log.Errorf("serve: scrape failed: %v", err)
+				}
+				select {
+				case <-ctx.Done():
+					return server.Close()
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", ":8080", "Address to serve /metrics, /healthz, /readyz and /report.* on")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "How often to re-run the export loop")
+	return cmd
+}
+
+func scrape(ctx context.Context, cfg Config, store *snapshotStore, drift map[string]int) error {
+	services, err := collectServices(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	store.set(services)
+
+	seen := map[string]bool{}
+	for _, s := range services {
+		labels := prometheus.Labels{"namespace": s.Namespace, "workload": s.Name, "kind": s.Kind}
+		cpuRequestGauge.With(labels).Set(float64(s.Request.CPU.MilliValue()))
+		cpuUsedGauge.With(labels).Set(float64(s.Usage.CPU.MilliValue()))
+		memRequestGauge.With(labels).Set(float64(s.Request.Memory.Value()))
+		memUsedGauge.With(labels).Set(float64(s.Usage.Memory.Value()))
+
+		for _, action := range []string{"Good", "Need update", "Need remove"} {
+			value := 0.0
+			if s.Action == action {
+				value = 1.0
+			}
+			verdictGauge.With(prometheus.Labels{"namespace": s.Namespace, "workload": s.Name, "kind": s.Kind, "action": action}).Set(value)
+		}
+
+		key := fmt.Sprintf("%s/%s/%s", s.Namespace, s.Kind, s.Name)
+		seen[key] = true
+		if s.Action == "Need update" {
+			drift[key]++
+		} else {
+			delete(drift, key)
+		}
+		driftGauge.With(labels).Set(float64(drift[key]))
+		if drift[key] >= driftThreshold {
+			log.Infof("serve: %s has been over-provisioned for %d consecutive scrapes", key, drift[key])
+		}
+	}
+
+	for key := range drift {
+		if !seen[key] {
+			delete(drift, key)
+		}
+	}
+	return nil
+}
+
+func reportHandler(store *snapshotStore, format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		services, ready := store.get()
+		if !ready {
+			http.Error(w, "no successful scrape yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		reporter, err := reporterFor(format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := reporter.Begin(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, s := range services {
+			if err := reporter.Write(s); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := reporter.End(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	RegisterHandler(statefulSetHandler{})
+}
+
+type statefulSetHandler struct{}
+
+func (statefulSetHandler) Kind() string { return "StatefulSet" }
+
+func (statefulSetHandler) List(ctx context.Context, cfg Config, ns corev1.Namespace) ([]Service, error) {
+	stses, err := cfg.KubeClient.AppsV1().StatefulSets(ns.GetName()).List(ctx, metav1.ListOptions{LabelSelector: cfg.WorkloadSelector})
+	if err != nil {
+		return nil, err
+	}
+	services := make([]Service, len(stses.Items))
+
+	for i, sts := range stses.Items {
+		selector, err := metav1.LabelSelectorAsMap(sts.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		services[i] = Service{
+			Kind:      "StatefulSet",
+			Namespace: sts.Namespace,
+			Name:      sts.Name,
+		}
+		for _, container := range sts.Spec.Template.Spec.Containers {
+			containerRequest := Resources{CPU: *container.Resources.Requests.Cpu(), Memory: *container.Resources.Requests.Memory()}
+			services[i].ContainerNames = append(services[i].ContainerNames, container.Name)
+			services[i].ContainerRequests = append(services[i].ContainerRequests, containerRequest)
+			services[i].Request.CPU.Add(containerRequest.CPU)
+			services[i].Request.Memory.Add(containerRequest.Memory)
+		}
+		usage, replicas, err := cfg.MetricsSource.Usage(ctx, Workload{
+			Kind:      "StatefulSet",
+			Namespace: ns.GetName(),
+			Name:      sts.Name,
+			Selector:  selector,
+		})
+		if err != nil {
+			return nil, err
+		}
+		services[i].Replicas = replicas
+		services[i].Usage = usage
+		services[i] = verdict(services[i], cfg.SafetyMargin)
+	}
+	return services, nil
+}
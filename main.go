@@ -1,40 +1,98 @@
 package main
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/google/martian/v3/log"
 	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/vietanhduong/resources-usage/export"
 )
 
 func newCommand() *cobra.Command {
-	var kubeconfig string
-	var exportCfg exportConfig
+	configFlags := genericclioptions.NewConfigFlags(true)
+	var exportCfg export.Config
+	var namespaces []string
+	var prometheusURL string
+	var lookback time.Duration
+	var cpuPercentile float64
+	var memPercentile float64
+	var historyDir string
+
+	buildExportConfig := func() (export.Config, error) {
+		cfg := exportCfg
+		restCfg, err := configFlags.ToRESTConfig()
+		if err != nil {
+			return cfg, err
+		}
+		if cfg.KubeClient, err = kubernetes.NewForConfig(restCfg); err != nil {
+			return cfg, err
+		}
+		if cfg.MetricClient, err = metrics.NewForConfig(restCfg); err != nil {
+			return cfg, err
+		}
+
+		cfg.Namespaces = namespaces
+		if namespace := configFlags.Namespace; namespace != nil && *namespace != "" {
+			cfg.Namespaces = append(cfg.Namespaces, *namespace)
+		}
+
+		switch {
+		case prometheusURL != "":
+			cfg.MetricsSource = export.NewPrometheusSource(export.PrometheusConfig{
+				URL:           prometheusURL,
+				Lookback:      lookback,
+				CPUPercentile: cpuPercentile,
+				MemPercentile: memPercentile,
+			})
+		default:
+			cfg.MetricsSource = export.NewDecayingHistogramSource(
+				export.NewMetricsServerSource(cfg.MetricClient), historyDir, cpuPercentile, memPercentile)
+		}
+		return cfg, nil
+	}
+
 	cmd := &cobra.Command{
 		Use: "resources-usage",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			log.SetLevel(log.Debug)
-			restCfg, err := newRESTConfig(kubeconfig)
+			cfg, err := buildExportConfig()
 			if err != nil {
 				return err
 			}
-			if exportCfg.KubeClient, err = kubernetes.NewForConfig(restCfg); err != nil {
-				return err
-			}
-			if exportCfg.MetricClient, err = metrics.NewForConfig(restCfg); err != nil {
-				return err
-			}
-			return export(exportCfg)
+			return export.Run(cfg)
 		},
 	}
-	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Kubernetes config file. Create a local config if no specified")
-	cmd.Flags().StringSliceVar(&exportCfg.IgnoreNamespaces, "ignore-namespaces", []string{"default", "kube-node-lease", "kube-public", "kube-system"}, "Ignore namespaces")
+	// These live on PersistentFlags (rather than Flags) so that the serve
+	// subcommand, which shares buildExportConfig, inherits them too.
+	configFlags.AddFlags(cmd.PersistentFlags())
+
+	cmd.PersistentFlags().StringSliceVar(&exportCfg.IgnoreNamespaces, "ignore-namespaces", []string{"default", "kube-node-lease", "kube-public", "kube-system"}, "Ignore namespaces")
+	cmd.PersistentFlags().StringSliceVar(&namespaces, "namespaces", nil, "Namespaces to report on (allow-list). Mutually exclusive with --ignore-namespaces")
+	cmd.PersistentFlags().StringVar(&exportCfg.NamespaceSelector, "namespace-selector", "", "Label selector to filter Namespace objects by, e.g. team=payments")
+	cmd.PersistentFlags().StringVar(&exportCfg.WorkloadSelector, "workload-selector", "", "Label selector to filter workloads by, e.g. tier=backend")
+	cmd.MarkFlagsMutuallyExclusive("namespaces", "ignore-namespaces")
+
+	cmd.PersistentFlags().StringSliceVar(&exportCfg.IncludeKinds, "include-kinds", nil, "Workload kinds to report on (default: all registered kinds). One of: Deployment, StatefulSet, DaemonSet, CronJob")
+	cmd.PersistentFlags().StringVar(&prometheusURL, "prometheus-url", "", "Prometheus base URL to read percentile usage from. If empty, falls back to metrics-server plus a local decaying-histogram across runs")
+	cmd.PersistentFlags().DurationVar(&lookback, "lookback", 7*24*time.Hour, "How far back to look when computing percentile usage from Prometheus")
+	cmd.PersistentFlags().Float64Var(&cpuPercentile, "cpu-percentile", 0.95, "CPU usage percentile to recommend requests from (e.g. 0.95 for p95)")
+	cmd.PersistentFlags().Float64Var(&memPercentile, "mem-percentile", 0.99, "Memory usage percentile to recommend requests from (e.g. 0.99 for p99)")
+	cmd.PersistentFlags().StringVar(&historyDir, "history-dir", filepath.Join(os.TempDir(), "resources-usage-history"), "Directory to persist local usage samples in when --prometheus-url is not set")
+	cmd.PersistentFlags().StringVarP(&exportCfg.Output, "output", "o", "csv", "Output format. One of: csv, json, yaml, table, markdown, junit")
+	cmd.PersistentFlags().StringVar(&exportCfg.ApplyMode, "apply-mode", "", "Emit machine-consumable recommendations for workloads needing an update, in addition to --output. One of: vpa, patch, kustomize")
+	cmd.PersistentFlags().StringVar(&exportCfg.OutDir, "out-dir", "", "Directory to write the Kustomize overlay to when --apply-mode=kustomize")
+	cmd.PersistentFlags().Float64Var(&exportCfg.SafetyMargin, "safety-margin", export.DefaultSafetyMargin, "Multiplier applied to observed usage when computing a recommended request, e.g. 1.2 for a 20% headroom")
+
+	cmd.AddCommand(export.NewServeCommand(func() (export.Config, error) {
+		log.SetLevel(log.Debug)
+		return buildExportConfig()
+	}))
 	return cmd
 }
 
@@ -43,30 +101,3 @@ func main() {
 		os.Exit(1)
 	}
 }
-
-func newRESTConfig(kubeconfig string) (*rest.Config, error) {
-	var fullKubeConfigPath string
-	var err error
-
-	if kubeconfig != "" {
-		fullKubeConfigPath, err = filepath.Abs(kubeconfig)
-		if err != nil {
-			return nil, fmt.Errorf("cannot expand path %s: %v", kubeconfig, err)
-		}
-	}
-
-	if fullKubeConfigPath != "" {
-		log.Debugf("Creating Kubernetes client from %s", fullKubeConfigPath)
-	} else {
-		log.Debugf("Creating in-cluster Kubernetes client")
-	}
-
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	loadingRules.DefaultClientConfig = &clientcmd.DefaultClientConfig
-	loadingRules.ExplicitPath = kubeconfig
-	overrides := clientcmd.ConfigOverrides{}
-	clientConfig := clientcmd.NewInteractiveDeferredLoadingClientConfig(loadingRules, &overrides, os.Stdin)
-	raw, _ := clientConfig.RawConfig()
-	log.Debugf("Current Context: %s", raw.CurrentContext)
-	return clientConfig.ClientConfig()
-}